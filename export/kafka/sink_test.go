@@ -0,0 +1,101 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/negbie/rtcp"
+)
+
+// fakeSyncProducer is a minimal sarama.SyncProducer that records the
+// messages it's handed instead of talking to a broker. It embeds the
+// interface so methods we never exercise (transactions, etc.) stay
+// satisfied without us having to stub them.
+type fakeSyncProducer struct {
+	sarama.SyncProducer
+	sent []*sarama.ProducerMessage
+}
+
+func (f *fakeSyncProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	f.sent = append(f.sent, msg)
+	return 0, int64(len(f.sent) - 1), nil
+}
+
+func (f *fakeSyncProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	f.sent = append(f.sent, msgs...)
+	return nil
+}
+
+func (f *fakeSyncProducer) Close() error { return nil }
+
+func testPacket() *rtcp.ExtendedReport {
+	return &rtcp.ExtendedReport{
+		SSRC: 42,
+		Reports: []rtcp.XRReportBlock{
+			&rtcp.VoIPMetricsReportBlock{SSRC: 42},
+		},
+	}
+}
+
+func TestSinkPublishUsesConfiguredEncoderAndPartitionKey(t *testing.T) {
+	producer := &fakeSyncProducer{}
+	s := &Sink{
+		producer:     producer,
+		topic:        "rtcp-xr",
+		encoder:      BinaryEncoder,
+		partitionKey: defaultPartitionKey,
+	}
+
+	pkt := testPacket()
+	assert.NoError(t, s.Publish(pkt))
+
+	assert.Len(t, producer.sent, 1)
+	assert.Equal(t, "rtcp-xr", producer.sent[0].Topic)
+
+	wantValue, err := pkt.Marshal()
+	assert.NoError(t, err)
+	gotValue, err := producer.sent[0].Value.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, wantValue, gotValue)
+
+	wantKey, err := sarama.ByteEncoder{0, 0, 0, 42}.Encode()
+	assert.NoError(t, err)
+	gotKey, err := producer.sent[0].Key.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, wantKey, gotKey)
+}
+
+func TestSinkPublishBatch(t *testing.T) {
+	producer := &fakeSyncProducer{}
+	s := &Sink{
+		producer:     producer,
+		topic:        "rtcp-xr",
+		encoder:      JSONEncoder,
+		partitionKey: defaultPartitionKey,
+	}
+
+	assert.NoError(t, s.PublishBatch([]rtcp.Packet{testPacket(), testPacket()}))
+	assert.Len(t, producer.sent, 2)
+}
+
+func TestJSONAndBinaryEncodersEncodeDifferently(t *testing.T) {
+	pkt := testPacket()
+
+	jsonData, err := JSONEncoder.Encode(pkt)
+	assert.NoError(t, err)
+
+	binData, err := BinaryEncoder.Encode(pkt)
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, jsonData, binData)
+
+	wantBin, err := pkt.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, wantBin, binData)
+}
+
+func TestDefaultPartitionKeyNoDestinationSSRC(t *testing.T) {
+	assert.Nil(t, defaultPartitionKey(&rtcp.ExtendedReport{}))
+}
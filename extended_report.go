@@ -8,16 +8,559 @@ import (
 type ExtendedReport struct {
 	// The synchronization source identifier for the originator of this XR packet.
 	SSRC uint32 `json:"XRSSRC"`
-	// The VoIP Metrics Report Block provides metrics for monitoring voice
-	// over IP (VoIP) calls.
-	Report *VoIPMetricsReportBlock `json:"VoIPMetricsReport"`
+	// Reports holds the RFC 3611 report blocks carried by this packet, in wire order.
+	Reports []XRReportBlock `json:"Reports"`
 }
 
+// XRReportBlock is implemented by each of the RFC 3611 extended report block
+// types (Loss RLE, Duplicate RLE, Packet Receipt Times, Receiver Reference
+// Time, DLRR, Statistics Summary and VoIP Metrics).
+type XRReportBlock interface {
+	Marshal() ([]byte, error)
+	Unmarshal(rawPacket []byte) error
+
+	destinationSSRC() []uint32
+}
+
+var _ Packet = (*ExtendedReport)(nil) // assert is a Packet
+
+const (
+	xrSSRCOffset   = headerLength
+	xrReportOffset = xrSSRCOffset + ssrcLength
+
+	// xrBlockHeaderLength is the size, in bytes, of the 4-byte header
+	// (block type, type-specific byte, block length) shared by every
+	// RFC 3611 report block.
+	xrBlockHeaderLength = 4
+)
+
+// RFC 3611 block type (BT) values.
+const (
+	xrBlockTypeLossRLE               = 1
+	xrBlockTypeDuplicateRLE          = 2
+	xrBlockTypePacketReceiptTimes    = 3
+	xrBlockTypeReceiverReferenceTime = 4
+	xrBlockTypeDLRR                  = 5
+	xrBlockTypeStatisticsSummary     = 6
+	xrBlockTypeVoIPMetrics           = 7
+)
+
+// Unmarshal decodes the ExtendedReport from binary
+func (xr *ExtendedReport) Unmarshal(rawPacket []byte) error {
+	// 0                   1                   2                   3
+	// 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+	// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	// |V=2|P|reserved |   PT=XR=207   |             length            |
+	// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	// |                              SSRC                             |
+	// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	// :                         report blocks                         :
+	// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+	if len(rawPacket) < (headerLength + ssrcLength) {
+		return errPacketTooShort
+	}
+
+	var h Header
+	if err := h.Unmarshal(rawPacket); err != nil {
+		return err
+	}
+
+	if h.Type != TypeExtendedReport {
+		return errWrongType
+	}
+
+	packetLength := (int(h.Length) + 1) * 4
+	if len(rawPacket) < packetLength || packetLength < xrReportOffset {
+		return errPacketTooShort
+	}
+
+	xr.SSRC = binary.BigEndian.Uint32(rawPacket[xrSSRCOffset:])
+
+	xr.Reports = nil
+	reportsBuf := rawPacket[xrReportOffset:packetLength]
+	for len(reportsBuf) > 0 {
+		if len(reportsBuf) < xrBlockHeaderLength {
+			return errPacketTooShort
+		}
+
+		blockLength := (int(binary.BigEndian.Uint16(reportsBuf[2:])) + 1) * 4
+		if len(reportsBuf) < blockLength {
+			return errPacketTooShort
+		}
+
+		report, err := unmarshalXRReportBlock(reportsBuf[:blockLength])
+		if err != nil {
+			return err
+		}
+		xr.Reports = append(xr.Reports, report)
+
+		reportsBuf = reportsBuf[blockLength:]
+	}
+
+	return nil
+}
+
+// unmarshalXRReportBlock dispatches to the concrete XRReportBlock
+// implementation for rawPacket's block type (BT), or returns errWrongType
+// for block types this package does not know about.
+func unmarshalXRReportBlock(rawPacket []byte) (XRReportBlock, error) {
+	var report XRReportBlock
+
+	switch rawPacket[0] {
+	case xrBlockTypeLossRLE:
+		report = new(LossRLEReportBlock)
+	case xrBlockTypeDuplicateRLE:
+		report = new(DuplicateRLEReportBlock)
+	case xrBlockTypePacketReceiptTimes:
+		report = new(PacketReceiptTimesReportBlock)
+	case xrBlockTypeReceiverReferenceTime:
+		report = new(ReceiverReferenceTimeReportBlock)
+	case xrBlockTypeDLRR:
+		report = new(DLRRReportBlock)
+	case xrBlockTypeStatisticsSummary:
+		report = new(StatisticsSummaryReportBlock)
+	case xrBlockTypeVoIPMetrics:
+		report = new(VoIPMetricsReportBlock)
+	default:
+		return nil, errWrongType
+	}
+
+	if err := report.Unmarshal(rawPacket); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// Marshal encodes the ExtendedReport in binary
+func (xr ExtendedReport) Marshal() ([]byte, error) {
+	payload := make([]byte, ssrcLength, ssrcLength+64)
+	binary.BigEndian.PutUint32(payload, xr.SSRC)
+
+	for _, report := range xr.Reports {
+		reportData, err := report.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		payload = append(payload, reportData...)
+	}
+
+	h := Header{
+		Type:   TypeExtendedReport,
+		Length: uint16(len(payload) / 4),
+	}
+
+	hData, err := h.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(hData, payload...), nil
+}
+
+// DestinationSSRC returns an array of SSRC values that this packet refers to.
+func (xr *ExtendedReport) DestinationSSRC() []uint32 {
+	ssrcs := make([]uint32, 0, len(xr.Reports))
+	for _, report := range xr.Reports {
+		ssrcs = append(ssrcs, report.destinationSSRC()...)
+	}
+	return ssrcs
+}
+
+// LossRLEReportBlock encodes a Loss Run Length Encoding Report Block, BT=1,
+// as described in RFC 3611, section 4.1. Chunks is a run-length encoding of
+// the receipt (1) or loss (0) of each packet in [BeginSeq, EndSeq).
+type LossRLEReportBlock struct {
+	Thinning uint8
+	SSRC     uint32
+	BeginSeq uint16
+	EndSeq   uint16
+	Chunks   []uint16
+}
+
+// Marshal encodes the LossRLEReportBlock in binary
+func (r LossRLEReportBlock) Marshal() ([]byte, error) {
+	return marshalXRRLEReportBlock(xrBlockTypeLossRLE, r.Thinning, r.SSRC, r.BeginSeq, r.EndSeq, r.Chunks)
+}
+
+// Unmarshal decodes the LossRLEReportBlock from binary
+func (r *LossRLEReportBlock) Unmarshal(rawPacket []byte) error {
+	if len(rawPacket) < xrBlockHeaderLength {
+		return errPacketTooShort
+	}
+
+	if rawPacket[0] != xrBlockTypeLossRLE {
+		return errWrongType
+	}
+
+	thinning, ssrc, beginSeq, endSeq, chunks, err := unmarshalXRRLEReportBlock(rawPacket)
+	if err != nil {
+		return err
+	}
+
+	r.Thinning, r.SSRC, r.BeginSeq, r.EndSeq, r.Chunks = thinning, ssrc, beginSeq, endSeq, chunks
+	return nil
+}
+
+func (r *LossRLEReportBlock) destinationSSRC() []uint32 {
+	return []uint32{r.SSRC}
+}
+
+// DuplicateRLEReportBlock encodes a Duplicate Run Length Encoding Report
+// Block, BT=2, as described in RFC 3611, section 4.2. It shares the Loss
+// RLE wire format; Chunks runs 1 for a duplicated packet and 0 otherwise.
+type DuplicateRLEReportBlock struct {
+	Thinning uint8
+	SSRC     uint32
+	BeginSeq uint16
+	EndSeq   uint16
+	Chunks   []uint16
+}
+
+// Marshal encodes the DuplicateRLEReportBlock in binary
+func (r DuplicateRLEReportBlock) Marshal() ([]byte, error) {
+	return marshalXRRLEReportBlock(xrBlockTypeDuplicateRLE, r.Thinning, r.SSRC, r.BeginSeq, r.EndSeq, r.Chunks)
+}
+
+// Unmarshal decodes the DuplicateRLEReportBlock from binary
+func (r *DuplicateRLEReportBlock) Unmarshal(rawPacket []byte) error {
+	if len(rawPacket) < xrBlockHeaderLength {
+		return errPacketTooShort
+	}
+
+	if rawPacket[0] != xrBlockTypeDuplicateRLE {
+		return errWrongType
+	}
+
+	thinning, ssrc, beginSeq, endSeq, chunks, err := unmarshalXRRLEReportBlock(rawPacket)
+	if err != nil {
+		return err
+	}
+
+	r.Thinning, r.SSRC, r.BeginSeq, r.EndSeq, r.Chunks = thinning, ssrc, beginSeq, endSeq, chunks
+	return nil
+}
+
+func (r *DuplicateRLEReportBlock) destinationSSRC() []uint32 {
+	return []uint32{r.SSRC}
+}
+
+// marshalXRRLEReportBlock encodes the RLE Report Block body shared by the
+// Loss RLE and Duplicate RLE block types, padding the chunk list with a
+// trailing null chunk (0x0000) when needed to reach a 32-bit boundary.
+func marshalXRRLEReportBlock(blockType uint8, thinning uint8, ssrc uint32, beginSeq, endSeq uint16, chunks []uint16) ([]byte, error) {
+	length := xrBlockHeaderLength + ssrcLength + 4 + len(chunks)*2
+	if length%4 != 0 {
+		length += 2
+	}
+
+	buf := make([]byte, length)
+	buf[0] = blockType
+	buf[1] = thinning & 0x0f
+	binary.BigEndian.PutUint16(buf[2:], uint16(length/4)-1)
+	binary.BigEndian.PutUint32(buf[4:], ssrc)
+	binary.BigEndian.PutUint16(buf[8:], beginSeq)
+	binary.BigEndian.PutUint16(buf[10:], endSeq)
+	for i, chunk := range chunks {
+		binary.BigEndian.PutUint16(buf[12+i*2:], chunk)
+	}
+
+	return buf, nil
+}
+
+// unmarshalXRRLEReportBlock decodes the RLE Report Block body shared by the
+// Loss RLE and Duplicate RLE block types. Per RFC 3611 section 4.1, a chunk
+// with all bits 0 is the "terminating null chunk": it is not itself a
+// chunk, marks the end of the chunk list, and is how marshalXRRLEReportBlock
+// pads an odd-length Chunks slice out to a 32-bit boundary, so parsing stops
+// there instead of reading the pad word back as real chunk data.
+func unmarshalXRRLEReportBlock(rawPacket []byte) (thinning uint8, ssrc uint32, beginSeq, endSeq uint16, chunks []uint16, err error) {
+	if len(rawPacket) < xrBlockHeaderLength+ssrcLength+4 {
+		err = errPacketTooShort
+		return
+	}
+
+	thinning = rawPacket[1] & 0x0f
+	ssrc = binary.BigEndian.Uint32(rawPacket[4:])
+	beginSeq = binary.BigEndian.Uint16(rawPacket[8:])
+	endSeq = binary.BigEndian.Uint16(rawPacket[10:])
+
+	chunkBuf := rawPacket[12:]
+	chunks = make([]uint16, 0, len(chunkBuf)/2)
+	for i := 0; i+1 < len(chunkBuf); i += 2 {
+		chunk := binary.BigEndian.Uint16(chunkBuf[i:])
+		if chunk == 0 {
+			break
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return
+}
+
+// PacketReceiptTimesReportBlock encodes a Packet Receipt Times Report
+// Block, BT=3, as described in RFC 3611, section 4.3. ReceiptTime holds one
+// entry per sequence number in [BeginSeq, EndSeq), 0 meaning not received.
+type PacketReceiptTimesReportBlock struct {
+	Thinning    uint8
+	SSRC        uint32
+	BeginSeq    uint16
+	EndSeq      uint16
+	ReceiptTime []uint32
+}
+
+// Marshal encodes the PacketReceiptTimesReportBlock in binary
+func (r PacketReceiptTimesReportBlock) Marshal() ([]byte, error) {
+	length := xrBlockHeaderLength + ssrcLength + 4 + len(r.ReceiptTime)*4
+
+	buf := make([]byte, length)
+	buf[0] = xrBlockTypePacketReceiptTimes
+	buf[1] = r.Thinning & 0x0f
+	binary.BigEndian.PutUint16(buf[2:], uint16(length/4)-1)
+	binary.BigEndian.PutUint32(buf[4:], r.SSRC)
+	binary.BigEndian.PutUint16(buf[8:], r.BeginSeq)
+	binary.BigEndian.PutUint16(buf[10:], r.EndSeq)
+	for i, t := range r.ReceiptTime {
+		binary.BigEndian.PutUint32(buf[12+i*4:], t)
+	}
+
+	return buf, nil
+}
+
+// Unmarshal decodes the PacketReceiptTimesReportBlock from binary
+func (r *PacketReceiptTimesReportBlock) Unmarshal(rawPacket []byte) error {
+	if len(rawPacket) < xrBlockHeaderLength+ssrcLength+4 {
+		return errPacketTooShort
+	}
+
+	if rawPacket[0] != xrBlockTypePacketReceiptTimes {
+		return errWrongType
+	}
+
+	r.Thinning = rawPacket[1] & 0x0f
+	r.SSRC = binary.BigEndian.Uint32(rawPacket[4:])
+	r.BeginSeq = binary.BigEndian.Uint16(rawPacket[8:])
+	r.EndSeq = binary.BigEndian.Uint16(rawPacket[10:])
+
+	timeBuf := rawPacket[12:]
+	r.ReceiptTime = make([]uint32, 0, len(timeBuf)/4)
+	for i := 0; i+3 < len(timeBuf); i += 4 {
+		r.ReceiptTime = append(r.ReceiptTime, binary.BigEndian.Uint32(timeBuf[i:]))
+	}
+
+	return nil
+}
+
+func (r *PacketReceiptTimesReportBlock) destinationSSRC() []uint32 {
+	return []uint32{r.SSRC}
+}
+
+// ReceiverReferenceTimeReportBlock encodes a Receiver Reference Time
+// Report Block, BT=4, as described in RFC 3611, section 4.4. It carries the
+// NTP timestamp of the XR packet's sender rather than of a remote source,
+// so it has no associated SSRC of its own.
+type ReceiverReferenceTimeReportBlock struct {
+	NTPTimestamp uint64
+}
+
+const xrReceiverReferenceTimeLength = xrBlockHeaderLength + 8
+
+// Marshal encodes the ReceiverReferenceTimeReportBlock in binary
+func (r ReceiverReferenceTimeReportBlock) Marshal() ([]byte, error) {
+	buf := make([]byte, xrReceiverReferenceTimeLength)
+	buf[0] = xrBlockTypeReceiverReferenceTime
+	binary.BigEndian.PutUint16(buf[2:], uint16(xrReceiverReferenceTimeLength/4)-1)
+	binary.BigEndian.PutUint64(buf[4:], r.NTPTimestamp)
+	return buf, nil
+}
+
+// Unmarshal decodes the ReceiverReferenceTimeReportBlock from binary
+func (r *ReceiverReferenceTimeReportBlock) Unmarshal(rawPacket []byte) error {
+	if len(rawPacket) < xrReceiverReferenceTimeLength {
+		return errPacketTooShort
+	}
+
+	if rawPacket[0] != xrBlockTypeReceiverReferenceTime {
+		return errWrongType
+	}
+
+	r.NTPTimestamp = binary.BigEndian.Uint64(rawPacket[4:])
+	return nil
+}
+
+func (r *ReceiverReferenceTimeReportBlock) destinationSSRC() []uint32 {
+	return nil
+}
+
+// DLRRReport is a single sub-block of a DLRRReportBlock, giving the last
+// receiver report timestamp and delay reported back for one SSRC.
+type DLRRReport struct {
+	SSRC   uint32
+	LastRR uint32
+	DLRR   uint32
+}
+
+// DLRRReportBlock encodes a DLRR Report Block, BT=5, as described in RFC
+// 3611, section 4.5, carrying zero or more per-SSRC DLRRReport entries.
+type DLRRReportBlock struct {
+	Reports []DLRRReport
+}
+
+// Marshal encodes the DLRRReportBlock in binary
+func (r DLRRReportBlock) Marshal() ([]byte, error) {
+	length := xrBlockHeaderLength + len(r.Reports)*12
+
+	buf := make([]byte, length)
+	buf[0] = xrBlockTypeDLRR
+	binary.BigEndian.PutUint16(buf[2:], uint16(length/4)-1)
+	for i, report := range r.Reports {
+		offset := xrBlockHeaderLength + i*12
+		binary.BigEndian.PutUint32(buf[offset:], report.SSRC)
+		binary.BigEndian.PutUint32(buf[offset+4:], report.LastRR)
+		binary.BigEndian.PutUint32(buf[offset+8:], report.DLRR)
+	}
+
+	return buf, nil
+}
+
+// Unmarshal decodes the DLRRReportBlock from binary
+func (r *DLRRReportBlock) Unmarshal(rawPacket []byte) error {
+	if len(rawPacket) < xrBlockHeaderLength {
+		return errPacketTooShort
+	}
+
+	if rawPacket[0] != xrBlockTypeDLRR {
+		return errWrongType
+	}
+
+	subBlocks := rawPacket[xrBlockHeaderLength:]
+	if len(subBlocks)%12 != 0 {
+		return errPacketTooShort
+	}
+
+	r.Reports = make([]DLRRReport, 0, len(subBlocks)/12)
+	for i := 0; i+11 < len(subBlocks); i += 12 {
+		r.Reports = append(r.Reports, DLRRReport{
+			SSRC:   binary.BigEndian.Uint32(subBlocks[i:]),
+			LastRR: binary.BigEndian.Uint32(subBlocks[i+4:]),
+			DLRR:   binary.BigEndian.Uint32(subBlocks[i+8:]),
+		})
+	}
+
+	return nil
+}
+
+func (r *DLRRReportBlock) destinationSSRC() []uint32 {
+	ssrcs := make([]uint32, 0, len(r.Reports))
+	for _, report := range r.Reports {
+		ssrcs = append(ssrcs, report.SSRC)
+	}
+	return ssrcs
+}
+
+// StatisticsSummaryReportBlock encodes a Statistics Summary Report Block,
+// BT=6, as described in RFC 3611, section 4.6.
+type StatisticsSummaryReportBlock struct {
+	LossReportFlag      bool
+	DuplicateReportFlag bool
+	JitterFlag          bool
+	// TTLorHopLimitType indicates whether MinTTLOrHL..DevTTLOrHL carry IPv4
+	// TTLs (1), IPv6 hop limits (2), or are unset (0).
+	TTLorHopLimitType uint8
+	SSRC              uint32
+	BeginSeq          uint16
+	EndSeq            uint16
+	LostPackets       uint32
+	DupPackets        uint32
+	MinJitter         uint32
+	MaxJitter         uint32
+	MeanJitter        uint32
+	DevJitter         uint32
+	MinTTLOrHL        uint8
+	MaxTTLOrHL        uint8
+	MeanTTLOrHL       uint8
+	DevTTLOrHL        uint8
+}
+
+const xrStatisticsSummaryLength = xrBlockHeaderLength + 36
+
+// Marshal encodes the StatisticsSummaryReportBlock in binary
+func (r StatisticsSummaryReportBlock) Marshal() ([]byte, error) {
+	buf := make([]byte, xrStatisticsSummaryLength)
+	buf[0] = xrBlockTypeStatisticsSummary
+
+	var flags uint8
+	if r.LossReportFlag {
+		flags |= 0x80
+	}
+	if r.DuplicateReportFlag {
+		flags |= 0x40
+	}
+	if r.JitterFlag {
+		flags |= 0x20
+	}
+	flags |= (r.TTLorHopLimitType & 0x03) << 3
+	buf[1] = flags
+
+	binary.BigEndian.PutUint16(buf[2:], uint16(xrStatisticsSummaryLength/4)-1)
+	binary.BigEndian.PutUint32(buf[4:], r.SSRC)
+	binary.BigEndian.PutUint16(buf[8:], r.BeginSeq)
+	binary.BigEndian.PutUint16(buf[10:], r.EndSeq)
+	binary.BigEndian.PutUint32(buf[12:], r.LostPackets)
+	binary.BigEndian.PutUint32(buf[16:], r.DupPackets)
+	binary.BigEndian.PutUint32(buf[20:], r.MinJitter)
+	binary.BigEndian.PutUint32(buf[24:], r.MaxJitter)
+	binary.BigEndian.PutUint32(buf[28:], r.MeanJitter)
+	binary.BigEndian.PutUint32(buf[32:], r.DevJitter)
+	buf[36] = r.MinTTLOrHL
+	buf[37] = r.MaxTTLOrHL
+	buf[38] = r.MeanTTLOrHL
+	buf[39] = r.DevTTLOrHL
+
+	return buf, nil
+}
+
+// Unmarshal decodes the StatisticsSummaryReportBlock from binary
+func (r *StatisticsSummaryReportBlock) Unmarshal(rawPacket []byte) error {
+	if len(rawPacket) < xrStatisticsSummaryLength {
+		return errPacketTooShort
+	}
+
+	if rawPacket[0] != xrBlockTypeStatisticsSummary {
+		return errWrongType
+	}
+
+	flags := rawPacket[1]
+	r.LossReportFlag = flags&0x80 != 0
+	r.DuplicateReportFlag = flags&0x40 != 0
+	r.JitterFlag = flags&0x20 != 0
+	r.TTLorHopLimitType = (flags >> 3) & 0x03
+
+	r.SSRC = binary.BigEndian.Uint32(rawPacket[4:])
+	r.BeginSeq = binary.BigEndian.Uint16(rawPacket[8:])
+	r.EndSeq = binary.BigEndian.Uint16(rawPacket[10:])
+	r.LostPackets = binary.BigEndian.Uint32(rawPacket[12:])
+	r.DupPackets = binary.BigEndian.Uint32(rawPacket[16:])
+	r.MinJitter = binary.BigEndian.Uint32(rawPacket[20:])
+	r.MaxJitter = binary.BigEndian.Uint32(rawPacket[24:])
+	r.MeanJitter = binary.BigEndian.Uint32(rawPacket[28:])
+	r.DevJitter = binary.BigEndian.Uint32(rawPacket[32:])
+	r.MinTTLOrHL = rawPacket[36]
+	r.MaxTTLOrHL = rawPacket[37]
+	r.MeanTTLOrHL = rawPacket[38]
+	r.DevTTLOrHL = rawPacket[39]
+
+	return nil
+}
+
+func (r *StatisticsSummaryReportBlock) destinationSSRC() []uint32 {
+	return []uint32{r.SSRC}
+}
+
+// VoIPMetricsReportBlock provides metrics for monitoring voice over IP
+// (VoIP) calls, BT=7, as described in RFC 3611, section 4.7.
 type VoIPMetricsReportBlock struct {
-	BlockType uint8
-	/*Reserved*/
-	BlockLength uint16
-	//4
 	SSRC uint32
 	//8
 	LossRate     uint8
@@ -50,22 +593,13 @@ type VoIPMetricsReportBlock struct {
 	//36
 }
 
-var _ Packet = (*ExtendedReport)(nil) // assert is a Packet
-
-const (
-	xrSSRCOffset   = headerLength
-	xrReportOffset = xrSSRCOffset + ssrcLength
-)
+const xrVoIPMetricsLength = xrBlockHeaderLength + 32
 
-// Unmarshal decodes the ExtendedReport from binary
-func (xr *ExtendedReport) Unmarshal(rawPacket []byte) error {
+// Marshal encodes the VoIPMetricsReportBlock in binary
+func (r VoIPMetricsReportBlock) Marshal() ([]byte, error) {
 	// 0                   1                   2                   3
 	// 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
 	// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
-	// |V=2|P|reserved |   PT=XR=207   |             length            |
-	// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
-	// |                              SSRC                             |
-	// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
 	// |     BT=7      |   reserved    |       block length = 8        |
 	// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
 	// |                       source                         |
@@ -85,70 +619,69 @@ func (xr *ExtendedReport) Unmarshal(rawPacket []byte) error {
 	// |          JB maximum           |          JB abs max           |
 	// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
 
-	if len(rawPacket) < (headerLength + ssrcLength) {
-		return errPacketTooShort
-	}
-
-	var h Header
-	if err := h.Unmarshal(rawPacket); err != nil {
-		return err
-	}
-
-	if h.Type != TypeExtendedReport {
-		return errWrongType
-	}
+	buf := make([]byte, xrVoIPMetricsLength)
+	buf[0] = xrBlockTypeVoIPMetrics
+	binary.BigEndian.PutUint16(buf[2:], uint16(xrVoIPMetricsLength/4)-1)
+	binary.BigEndian.PutUint32(buf[4:], r.SSRC)
+	buf[8] = r.LossRate
+	buf[9] = r.DiscardRate
+	buf[10] = r.BurstDensity
+	buf[11] = r.GapDensity
+	binary.BigEndian.PutUint16(buf[12:], r.BurstDuration)
+	binary.BigEndian.PutUint16(buf[14:], r.GapDuration)
+	binary.BigEndian.PutUint16(buf[16:], r.RoundTripDelay)
+	binary.BigEndian.PutUint16(buf[18:], r.EndSystemDelay)
+	buf[20] = r.SignalLevel
+	buf[21] = r.NoiseLevel
+	buf[22] = r.EchoReturnLoss
+	buf[23] = r.GapThreshold
+	buf[24] = r.RFactor
+	buf[25] = r.ExternalRFactor
+	buf[26] = r.MeanOpinionScoreListening
+	buf[27] = r.MeanOpinionScoreConversation
+	buf[28] = r.RXConfig
+	binary.BigEndian.PutUint16(buf[30:], r.JitterBufferNominalDelay)
+	binary.BigEndian.PutUint16(buf[32:], r.JitterBufferMaximumDelay)
+	binary.BigEndian.PutUint16(buf[34:], r.JitterBufferAbsoluteMaximumDelay)
 
-	if len(rawPacket[xrSSRCOffset:]) != 40 {
-		return errPacketTooShort
-	}
-
-	xr.SSRC = binary.BigEndian.Uint32(rawPacket[xrSSRCOffset:])
+	return buf, nil
+}
 
-	if len(rawPacket[xrReportOffset:]) != 36 {
+// Unmarshal decodes the VoIPMetricsReportBlock from binary
+func (r *VoIPMetricsReportBlock) Unmarshal(rawPacket []byte) error {
+	if len(rawPacket) < xrVoIPMetricsLength {
 		return errPacketTooShort
 	}
 
-	xr.Report = new(VoIPMetricsReportBlock)
-	xr.Report.BlockType = rawPacket[xrReportOffset]
-
-	if xr.Report.BlockType != 7 {
-		return nil
+	if rawPacket[0] != xrBlockTypeVoIPMetrics {
+		return errWrongType
 	}
 
-	/*Reserved*/
-	xr.Report.BlockLength = binary.BigEndian.Uint16(rawPacket[xrReportOffset+2:])
-	xr.Report.SSRC = binary.BigEndian.Uint32(rawPacket[xrReportOffset+4:])
-	xr.Report.LossRate = rawPacket[xrReportOffset+8]
-	xr.Report.DiscardRate = rawPacket[xrReportOffset+9]
-	xr.Report.BurstDensity = rawPacket[xrReportOffset+10]
-	xr.Report.GapDensity = rawPacket[xrReportOffset+11]
-	xr.Report.BurstDuration = binary.BigEndian.Uint16(rawPacket[xrReportOffset+12:])
-	xr.Report.GapDuration = binary.BigEndian.Uint16(rawPacket[xrReportOffset+14:])
-	xr.Report.RoundTripDelay = binary.BigEndian.Uint16(rawPacket[xrReportOffset+16:])
-	xr.Report.EndSystemDelay = binary.BigEndian.Uint16(rawPacket[xrReportOffset+18:])
-	xr.Report.SignalLevel = rawPacket[xrReportOffset+20]
-	xr.Report.NoiseLevel = rawPacket[xrReportOffset+21]
-	xr.Report.EchoReturnLoss = rawPacket[xrReportOffset+22]
-	xr.Report.GapThreshold = rawPacket[xrReportOffset+23]
-	xr.Report.RFactor = rawPacket[xrReportOffset+24]
-	xr.Report.ExternalRFactor = rawPacket[xrReportOffset+25]
-	xr.Report.MeanOpinionScoreListening = rawPacket[xrReportOffset+26]
-	xr.Report.MeanOpinionScoreConversation = rawPacket[xrReportOffset+27]
-	xr.Report.RXConfig = rawPacket[xrReportOffset+28]
-	/*Reserved*/
-	xr.Report.JitterBufferNominalDelay = binary.BigEndian.Uint16(rawPacket[xrReportOffset+30:])
-	xr.Report.JitterBufferMaximumDelay = binary.BigEndian.Uint16(rawPacket[xrReportOffset+32:])
-	xr.Report.JitterBufferAbsoluteMaximumDelay = binary.BigEndian.Uint16(rawPacket[xrReportOffset+34:])
+	r.SSRC = binary.BigEndian.Uint32(rawPacket[4:])
+	r.LossRate = rawPacket[8]
+	r.DiscardRate = rawPacket[9]
+	r.BurstDensity = rawPacket[10]
+	r.GapDensity = rawPacket[11]
+	r.BurstDuration = binary.BigEndian.Uint16(rawPacket[12:])
+	r.GapDuration = binary.BigEndian.Uint16(rawPacket[14:])
+	r.RoundTripDelay = binary.BigEndian.Uint16(rawPacket[16:])
+	r.EndSystemDelay = binary.BigEndian.Uint16(rawPacket[18:])
+	r.SignalLevel = rawPacket[20]
+	r.NoiseLevel = rawPacket[21]
+	r.EchoReturnLoss = rawPacket[22]
+	r.GapThreshold = rawPacket[23]
+	r.RFactor = rawPacket[24]
+	r.ExternalRFactor = rawPacket[25]
+	r.MeanOpinionScoreListening = rawPacket[26]
+	r.MeanOpinionScoreConversation = rawPacket[27]
+	r.RXConfig = rawPacket[28]
+	r.JitterBufferNominalDelay = binary.BigEndian.Uint16(rawPacket[30:])
+	r.JitterBufferMaximumDelay = binary.BigEndian.Uint16(rawPacket[32:])
+	r.JitterBufferAbsoluteMaximumDelay = binary.BigEndian.Uint16(rawPacket[34:])
 
 	return nil
 }
 
-// Marshal encodes the ExtendedReport in binary
-func (xr ExtendedReport) Marshal() ([]byte, error) {
-	return nil, nil
-}
-
-// DestinationSSRC returns an array of SSRC values that this packet refers to.
-func (xr *ExtendedReport) DestinationSSRC() []uint32 {
-	return nil
+func (r *VoIPMetricsReportBlock) destinationSSRC() []uint32 {
+	return []uint32{r.SSRC}
 }
@@ -0,0 +1,240 @@
+package rtcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtendedReportRoundTrip(t *testing.T) {
+	cases := map[string]struct {
+		xr ExtendedReport
+	}{
+		"LossRLE": {
+			xr: ExtendedReport{
+				SSRC: 1,
+				Reports: []XRReportBlock{
+					&LossRLEReportBlock{
+						Thinning: 0,
+						SSRC:     0x01020304,
+						BeginSeq: 1000,
+						EndSeq:   1010,
+						Chunks:   []uint16{0x1234, 0x5678, 0x9abc, 0xdef0},
+					},
+				},
+			},
+		},
+		"DuplicateRLE": {
+			xr: ExtendedReport{
+				SSRC: 1,
+				Reports: []XRReportBlock{
+					&DuplicateRLEReportBlock{
+						Thinning: 3,
+						SSRC:     0x01020304,
+						BeginSeq: 1000,
+						EndSeq:   1002,
+						Chunks:   []uint16{0x0001, 0x0002},
+					},
+				},
+			},
+		},
+		"LossRLEOddChunks": {
+			// An odd chunk count forces marshalXRRLEReportBlock to pad with
+			// a trailing terminating null chunk (0x0000); it must not come
+			// back as a fourth chunk on Unmarshal.
+			xr: ExtendedReport{
+				SSRC: 1,
+				Reports: []XRReportBlock{
+					&LossRLEReportBlock{
+						SSRC:     0x01020304,
+						BeginSeq: 1000,
+						EndSeq:   1003,
+						Chunks:   []uint16{0xaaaa, 0xbbbb, 0xcccc},
+					},
+				},
+			},
+		},
+		"PacketReceiptTimes": {
+			xr: ExtendedReport{
+				SSRC: 1,
+				Reports: []XRReportBlock{
+					&PacketReceiptTimesReportBlock{
+						Thinning:    0,
+						SSRC:        0x01020304,
+						BeginSeq:    1000,
+						EndSeq:      1003,
+						ReceiptTime: []uint32{100, 200, 300},
+					},
+				},
+			},
+		},
+		"ReceiverReferenceTime": {
+			xr: ExtendedReport{
+				SSRC: 1,
+				Reports: []XRReportBlock{
+					&ReceiverReferenceTimeReportBlock{
+						NTPTimestamp: 0x0102030405060708,
+					},
+				},
+			},
+		},
+		"DLRR": {
+			xr: ExtendedReport{
+				SSRC: 1,
+				Reports: []XRReportBlock{
+					&DLRRReportBlock{
+						Reports: []DLRRReport{
+							{SSRC: 0x01020304, LastRR: 0x11121314, DLRR: 0x21222324},
+							{SSRC: 0x05060708, LastRR: 0x15161718, DLRR: 0x25262728},
+						},
+					},
+				},
+			},
+		},
+		"StatisticsSummary": {
+			xr: ExtendedReport{
+				SSRC: 1,
+				Reports: []XRReportBlock{
+					&StatisticsSummaryReportBlock{
+						LossReportFlag:      true,
+						DuplicateReportFlag: true,
+						JitterFlag:          true,
+						TTLorHopLimitType:   1,
+						SSRC:                0x01020304,
+						BeginSeq:            1000,
+						EndSeq:              2000,
+						LostPackets:         5,
+						DupPackets:          2,
+						MinJitter:           10,
+						MaxJitter:           100,
+						MeanJitter:          50,
+						DevJitter:           20,
+						MinTTLOrHL:          1,
+						MaxTTLOrHL:          64,
+						MeanTTLOrHL:         32,
+						DevTTLOrHL:          4,
+					},
+				},
+			},
+		},
+		"VoIPMetrics": {
+			xr: ExtendedReport{
+				SSRC: 1,
+				Reports: []XRReportBlock{
+					&VoIPMetricsReportBlock{
+						SSRC:                             0x01020304,
+						LossRate:                         1,
+						DiscardRate:                      2,
+						BurstDensity:                     3,
+						GapDensity:                       4,
+						BurstDuration:                    5,
+						GapDuration:                      6,
+						RoundTripDelay:                   7,
+						EndSystemDelay:                   8,
+						SignalLevel:                      9,
+						NoiseLevel:                       10,
+						EchoReturnLoss:                   11,
+						GapThreshold:                     12,
+						RFactor:                          13,
+						ExternalRFactor:                  14,
+						MeanOpinionScoreListening:        15,
+						MeanOpinionScoreConversation:     16,
+						RXConfig:                         17,
+						JitterBufferNominalDelay:         18,
+						JitterBufferMaximumDelay:         19,
+						JitterBufferAbsoluteMaximumDelay: 20,
+					},
+				},
+			},
+		},
+		"MultipleReports": {
+			xr: ExtendedReport{
+				SSRC: 42,
+				Reports: []XRReportBlock{
+					&ReceiverReferenceTimeReportBlock{NTPTimestamp: 1},
+					&VoIPMetricsReportBlock{SSRC: 2},
+				},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			data, err := c.xr.Marshal()
+			assert.NoError(t, err)
+
+			var decoded ExtendedReport
+			assert.NoError(t, decoded.Unmarshal(data))
+			assert.Equal(t, c.xr, decoded)
+		})
+	}
+}
+
+func TestExtendedReportUnmarshalMalformed(t *testing.T) {
+	cases := map[string]struct {
+		rawPacket []byte
+	}{
+		"HeaderLengthBelowReportOffset": {
+			// V=2, P=0, PT=207 (XR), Length=0 claims a 4-byte packet,
+			// which is shorter than the 8-byte header+SSRC we already
+			// require before any report blocks can start.
+			rawPacket: []byte{
+				0x80, 0xcf, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x01,
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+			},
+		},
+		"ShortHeader": {
+			rawPacket: []byte{0x80, 0xcf, 0x00, 0x00},
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			var xr ExtendedReport
+			assert.Equal(t, errPacketTooShort, xr.Unmarshal(c.rawPacket))
+		})
+	}
+}
+
+// TestXRReportBlockUnmarshalShortInput exercises the Loss RLE, Duplicate
+// RLE and DLRR blocks' Unmarshal standalone, as the package's own callers
+// do, rather than only
+// through ExtendedReport.Unmarshal's dispatch (which never passes it
+// anything shorter than xrBlockHeaderLength).
+func TestXRReportBlockUnmarshalShortInput(t *testing.T) {
+	cases := map[string]XRReportBlock{
+		"LossRLE":      &LossRLEReportBlock{},
+		"DuplicateRLE": &DuplicateRLEReportBlock{},
+		"DLRR":         &DLRRReportBlock{},
+	}
+
+	for name, block := range cases {
+		block := block
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, errPacketTooShort, block.Unmarshal(nil))
+			assert.Equal(t, errPacketTooShort, block.Unmarshal([]byte{0x01, 0x00}))
+		})
+	}
+}
+
+func TestExtendedReportDestinationSSRC(t *testing.T) {
+	xr := ExtendedReport{
+		SSRC: 1,
+		Reports: []XRReportBlock{
+			&LossRLEReportBlock{SSRC: 2},
+			&DuplicateRLEReportBlock{SSRC: 3},
+			&PacketReceiptTimesReportBlock{SSRC: 4},
+			&ReceiverReferenceTimeReportBlock{NTPTimestamp: 5},
+			&DLRRReportBlock{Reports: []DLRRReport{{SSRC: 6}, {SSRC: 7}}},
+			&StatisticsSummaryReportBlock{SSRC: 8},
+			&VoIPMetricsReportBlock{SSRC: 9},
+		},
+	}
+
+	assert.Equal(t, []uint32{2, 3, 4, 6, 7, 8, 9}, xr.DestinationSSRC())
+}
@@ -0,0 +1,168 @@
+// Package pcap replays RTCP compound packets out of offline capture files,
+// so they can be fed through rtcp.Unmarshal without hand-rolling a capture
+// parsing loop.
+package pcap
+
+import (
+	"net/netip"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/ip4defrag"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+
+	"github.com/negbie/rtcp"
+)
+
+// RTCP packet types (SR, RR, SDES, BYE, APP, RTPFB, PSFB, XR, ...) occupy
+// wire byte values 192-223; RFC 5761 reserves this range so a packet
+// multiplexed onto the same 5-tuple as RTP can be told apart from RTP,
+// whose payload types never land here.
+const (
+	rtcpPayloadTypeMin = 192
+	rtcpPayloadTypeMax = 223
+)
+
+// Reader replays the RTCP compound packets found in a pcap capture file.
+type Reader struct {
+	handle *pcap.Handle
+	source *gopacket.PacketSource
+	defrag *ip4defrag.IPv4Defragmenter
+}
+
+// Open opens the capture file at path for reading.
+func Open(path string) (*Reader, error) {
+	handle, err := pcap.OpenOffline(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{
+		handle: handle,
+		source: gopacket.NewPacketSource(handle, handle.LinkType()),
+		defrag: ip4defrag.NewIPv4Defragmenter(),
+	}, nil
+}
+
+// Close releases the underlying capture handle.
+func (r *Reader) Close() {
+	r.handle.Close()
+}
+
+// Next returns the next UDP datagram in the capture whose payload decodes
+// as one or more compound RTCP packets. It reassembles fragmented IPv4
+// datagrams before inspecting them, and skips anything that is not UDP or
+// whose payload type byte falls outside the RFC 5761 RTCP range (so RTP
+// packets multiplexed onto the same 5-tuple are not misread as RTCP). It
+// returns io.EOF, wrapped by the underlying pcap source, once the capture
+// is exhausted.
+func (r *Reader) Next() (ts time.Time, src, dst netip.AddrPort, pkts []rtcp.Packet, err error) {
+	for {
+		packet, err := r.source.NextPacket()
+		if err != nil {
+			return time.Time{}, netip.AddrPort{}, netip.AddrPort{}, nil, err
+		}
+
+		packet, reassembledIP, ok := r.reassemble(packet)
+		if !ok {
+			continue
+		}
+
+		udpLayer := packet.Layer(layers.LayerTypeUDP)
+		if udpLayer == nil {
+			continue
+		}
+		udp, _ := udpLayer.(*layers.UDP)
+
+		srcAddr, dstAddr, ok := addrPorts(packet, udp, reassembledIP)
+		if !ok {
+			continue
+		}
+
+		if !looksLikeRTCP(udp.Payload) {
+			continue
+		}
+
+		decoded, err := rtcp.Unmarshal(udp.Payload)
+		if err != nil {
+			continue
+		}
+
+		return packet.Metadata().Timestamp, srcAddr, dstAddr, decoded, nil
+	}
+}
+
+// reassemble runs packet through the IPv4 defragmenter when it carries a
+// fragmented IPv4 datagram, re-decoding the reassembled datagram's upper
+// layers. ok is false for a fragment that is not yet complete, and for
+// anything the defragmenter rejects. reassembledIP is non-nil only when
+// reassembly happened: the returned packet, built from the reassembled
+// payload alone, carries no IPv4 layer of its own, so the caller must read
+// the addresses off reassembledIP instead of looking them up on packet.
+func (r *Reader) reassemble(packet gopacket.Packet) (_ gopacket.Packet, reassembledIP *layers.IPv4, ok bool) {
+	ipLayer := packet.Layer(layers.LayerTypeIPv4)
+	if ipLayer == nil {
+		return packet, nil, true
+	}
+
+	ip4 := ipLayer.(*layers.IPv4)
+	if ip4.FragOffset == 0 && ip4.Flags&layers.IPv4MoreFragments == 0 {
+		return packet, nil, true
+	}
+
+	reassembled, err := r.defrag.DefragIPv4WithTimestamp(ip4, packet.Metadata().Timestamp)
+	if err != nil || reassembled == nil {
+		return nil, nil, false
+	}
+
+	payload := gopacket.NewPacket(reassembled.Payload, reassembled.NextLayerType(), gopacket.Default)
+	return payload, reassembled, true
+}
+
+// addrPorts returns the source and destination of packet's UDP datagram.
+// ipOverride, when non-nil, is used in place of looking for an IPv4 layer
+// on packet (the case after IPv4 reassembly, whose reassembled packet no
+// longer carries one).
+func addrPorts(packet gopacket.Packet, udp *layers.UDP, ipOverride *layers.IPv4) (src, dst netip.AddrPort, ok bool) {
+	if ipOverride != nil {
+		return addrPortsFromIP(ipOverride.SrcIP, ipOverride.DstIP, udp)
+	}
+
+	if ipLayer := packet.Layer(layers.LayerTypeIPv4); ipLayer != nil {
+		ip4 := ipLayer.(*layers.IPv4)
+		return addrPortsFromIP(ip4.SrcIP, ip4.DstIP, udp)
+	}
+
+	if ipLayer := packet.Layer(layers.LayerTypeIPv6); ipLayer != nil {
+		ip6 := ipLayer.(*layers.IPv6)
+		return addrPortsFromIP(ip6.SrcIP, ip6.DstIP, udp)
+	}
+
+	return netip.AddrPort{}, netip.AddrPort{}, false
+}
+
+func addrPortsFromIP(srcIP, dstIP []byte, udp *layers.UDP) (src, dst netip.AddrPort, ok bool) {
+	srcAddr, srcOk := netip.AddrFromSlice(srcIP)
+	dstAddr, dstOk := netip.AddrFromSlice(dstIP)
+	if !srcOk || !dstOk {
+		return netip.AddrPort{}, netip.AddrPort{}, false
+	}
+
+	return netip.AddrPortFrom(srcAddr, uint16(udp.SrcPort)), netip.AddrPortFrom(dstAddr, uint16(udp.DstPort)), true
+}
+
+// looksLikeRTCP reports whether payload begins with an RTCP header: version
+// 2 and a packet type in the RFC 5761 RTCP range. RTP packets multiplexed
+// onto the same 5-tuple use payload types outside that range.
+func looksLikeRTCP(payload []byte) bool {
+	if len(payload) < 4 {
+		return false
+	}
+	if payload[0]>>6 != 2 {
+		return false
+	}
+
+	pt := payload[1]
+	return pt >= rtcpPayloadTypeMin && pt <= rtcpPayloadTypeMax
+}
@@ -0,0 +1,111 @@
+package pcap
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/ip4defrag"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLooksLikeRTCP(t *testing.T) {
+	cases := map[string]struct {
+		payload []byte
+		want    bool
+	}{
+		"tooShort":     {payload: []byte{0x80, 0xc8}, want: false},
+		"wrongVersion": {payload: []byte{0x00, 0xc8, 0, 0}, want: false},
+		"rtpDynamicPT": {payload: []byte{0x80, 0x60, 0, 0}, want: false}, // PT=96, a genuine RTP dynamic payload type
+		"senderReport": {payload: []byte{0x80, 0xc8, 0, 0}, want: true},  // PT=200 (SR)
+		"xrReport":     {payload: []byte{0x80, 0xcf, 0, 0}, want: true},  // PT=207 (XR)
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.want, looksLikeRTCP(c.payload))
+		})
+	}
+}
+
+func TestAddrPortsFromIP(t *testing.T) {
+	udp := &layers.UDP{SrcPort: 5004, DstPort: 5005}
+
+	src, dst, ok := addrPortsFromIP(net.IPv4(10, 0, 0, 1).To4(), net.IPv4(10, 0, 0, 2).To4(), udp)
+	assert.True(t, ok)
+	assert.Equal(t, "10.0.0.1:5004", src.String())
+	assert.Equal(t, "10.0.0.2:5005", dst.String())
+
+	_, _, ok = addrPortsFromIP(nil, net.IPv4(10, 0, 0, 2).To4(), udp)
+	assert.False(t, ok)
+}
+
+// buildIPv4Fragment serializes a single Ethernet+IPv4 fragment carrying
+// payload, then decodes it back into a gopacket.Packet the way
+// gopacket.PacketSource would hand one to Reader.Next.
+func buildIPv4Fragment(t *testing.T, srcIP, dstIP net.IP, id uint16, fragOffsetBytes int, moreFragments bool, payload []byte) gopacket.Packet {
+	t.Helper()
+
+	var flags layers.IPv4Flag
+	if moreFragments {
+		flags = layers.IPv4MoreFragments
+	}
+
+	eth := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0x02, 0, 0, 0, 0, 1},
+		DstMAC:       net.HardwareAddr{0x02, 0, 0, 0, 0, 2},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:    4,
+		IHL:        5,
+		TTL:        64,
+		Id:         id,
+		Flags:      flags,
+		FragOffset: uint16(fragOffsetBytes / 8),
+		Protocol:   layers.IPProtocolUDP,
+		SrcIP:      srcIP,
+		DstIP:      dstIP,
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	require.NoError(t, gopacket.SerializeLayers(buf, opts, eth, ip, gopacket.Payload(payload)))
+
+	return gopacket.NewPacket(buf.Bytes(), layers.LayerTypeEthernet, gopacket.Default)
+}
+
+func TestReaderReassembleRecoversFragmentedDatagramAddresses(t *testing.T) {
+	srcIP := net.IPv4(10, 0, 0, 1).To4()
+	dstIP := net.IPv4(10, 0, 0, 2).To4()
+
+	// A minimal fake UDP datagram: an 8-byte header (ports 5004 -> 5005)
+	// followed by a payload long enough to need a second fragment.
+	udpHeader := []byte{0x13, 0x8c, 0x13, 0x8d, 0x00, 0x10, 0x00, 0x00}
+	fullDatagram := append(append([]byte{}, udpHeader...), []byte{1, 2, 3, 4, 5, 6, 7, 8}...)
+
+	const splitAt = 8 // must be a multiple of 8, the IPv4 fragment unit
+	first := buildIPv4Fragment(t, srcIP, dstIP, 42, 0, true, fullDatagram[:splitAt])
+	second := buildIPv4Fragment(t, srcIP, dstIP, 42, splitAt, false, fullDatagram[splitAt:])
+
+	r := &Reader{defrag: ip4defrag.NewIPv4Defragmenter()}
+
+	_, _, ok := r.reassemble(first)
+	assert.False(t, ok, "a lone first fragment should not complete reassembly")
+
+	packet, reassembledIP, ok := r.reassemble(second)
+	require.True(t, ok, "the second fragment should complete reassembly")
+	require.NotNil(t, reassembledIP, "reassembly must report the addresses the discarded IPv4 layer carried")
+
+	udpLayer := packet.Layer(layers.LayerTypeUDP)
+	require.NotNil(t, udpLayer)
+	udp := udpLayer.(*layers.UDP)
+
+	src, dst, ok := addrPorts(packet, udp, reassembledIP)
+	require.True(t, ok)
+	assert.Equal(t, "10.0.0.1:5004", src.String())
+	assert.Equal(t, "10.0.0.2:5005", dst.String())
+}
@@ -0,0 +1,130 @@
+// Package rtcpmetrics turns the VoIP Metrics block of a decoded RTCP XR
+// packet into labeled gauges, so operators can wire call quality straight
+// into an existing go-metrics or Prometheus-backed dashboard.
+package rtcpmetrics
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/go-metrics"
+
+	"github.com/negbie/rtcp"
+)
+
+// GaugeSetter is the subset of go-metrics' MetricSink used by Registry.
+// *metrics.Metrics satisfies it directly; callers who want a Prometheus
+// backend instead can implement it with their own thin adapter.
+type GaugeSetter interface {
+	SetGaugeWithLabels(key []string, val float32, labels []metrics.Label)
+}
+
+// unavailable is the RFC 3611 sentinel meaning a VoIP metrics field was not
+// measured (checked for signal level, noise level, RERL, R factor, external
+// R factor, MOS-LQ and MOS-CQ).
+const unavailable = 127
+
+// Registry maps VoIP Metrics Report Block fields onto named gauges,
+// labeled by SSRC and an optional caller-supplied call ID.
+type Registry struct {
+	sink   GaugeSetter
+	prefix []string
+}
+
+// NewRegistry returns a Registry that reports gauges under prefix (e.g.
+// []string{"rtcp", "voip"}) via sink.
+func NewRegistry(sink GaugeSetter, prefix ...string) *Registry {
+	return &Registry{sink: sink, prefix: prefix}
+}
+
+// Observe reports gauges for every VoIP Metrics Report Block carried by xr.
+// callID is attached as a label when non-empty, so metrics from the same
+// SSRC reused across calls stay distinguishable.
+func (r *Registry) Observe(xr *rtcp.ExtendedReport, callID string) {
+	for _, report := range xr.Reports {
+		voip, ok := report.(*rtcp.VoIPMetricsReportBlock)
+		if !ok {
+			continue
+		}
+		r.observeVoIPMetrics(voip, callID)
+	}
+}
+
+func (r *Registry) observeVoIPMetrics(voip *rtcp.VoIPMetricsReportBlock, callID string) {
+	labels := []metrics.Label{{Name: "ssrc", Value: strconv.FormatUint(uint64(voip.SSRC), 10)}}
+	if callID != "" {
+		labels = append(labels, metrics.Label{Name: "call_id", Value: callID})
+	}
+
+	r.gauge("loss_rate", q8ToRatio(voip.LossRate), labels)
+	r.gauge("discard_rate", q8ToRatio(voip.DiscardRate), labels)
+	r.gauge("burst_density", q8ToRatio(voip.BurstDensity), labels)
+	r.gauge("gap_density", q8ToRatio(voip.GapDensity), labels)
+
+	r.gauge("burst_duration_seconds", msToSeconds(voip.BurstDuration), labels)
+	r.gauge("gap_duration_seconds", msToSeconds(voip.GapDuration), labels)
+	r.gauge("round_trip_delay_seconds", msToSeconds(voip.RoundTripDelay), labels)
+	r.gauge("end_system_delay_seconds", msToSeconds(voip.EndSystemDelay), labels)
+	r.gauge("jitter_buffer_nominal_delay_seconds", msToSeconds(voip.JitterBufferNominalDelay), labels)
+	r.gauge("jitter_buffer_maximum_delay_seconds", msToSeconds(voip.JitterBufferMaximumDelay), labels)
+	r.gauge("jitter_buffer_absolute_maximum_delay_seconds", msToSeconds(voip.JitterBufferAbsoluteMaximumDelay), labels)
+
+	if _, ok := decodeUnavailable127(voip.SignalLevel); ok {
+		r.gauge("signal_level_dbm", float32(int8(voip.SignalLevel)), labels)
+	}
+	if _, ok := decodeUnavailable127(voip.NoiseLevel); ok {
+		r.gauge("noise_level_dbm", float32(int8(voip.NoiseLevel)), labels)
+	}
+	if rerl, ok := decodeUnavailable127(voip.EchoReturnLoss); ok {
+		r.gauge("echo_return_loss_db", rerl, labels)
+	}
+
+	if rFactor, ok := decodeUnavailable127(voip.RFactor); ok {
+		r.gauge("r_factor", rFactor, labels)
+	}
+	if extRFactor, ok := decodeUnavailable127(voip.ExternalRFactor); ok {
+		r.gauge("external_r_factor", extRFactor, labels)
+	}
+	if mosLQ, ok := decodeMOS(voip.MeanOpinionScoreListening); ok {
+		r.gauge("mos_lq", mosLQ, labels)
+	}
+	if mosCQ, ok := decodeMOS(voip.MeanOpinionScoreConversation); ok {
+		r.gauge("mos_cq", mosCQ, labels)
+	}
+}
+
+func (r *Registry) gauge(name string, val float32, labels []metrics.Label) {
+	key := make([]string, len(r.prefix), len(r.prefix)+1)
+	copy(key, r.prefix)
+	key = append(key, name)
+	r.sink.SetGaugeWithLabels(key, val, labels)
+}
+
+// q8ToRatio converts an RFC 3611 Q.8 fixed-point byte (value/256) into a
+// 0.0-1.0 ratio.
+func q8ToRatio(raw uint8) float32 {
+	return float32(raw) / 256.0
+}
+
+// msToSeconds converts an RFC 3611 millisecond delay field into seconds.
+func msToSeconds(ms uint16) float32 {
+	return float32(ms) / 1000.0
+}
+
+// decodeUnavailable127 reports whether raw, an RFC 3611 VoIP metrics byte
+// such as the R factor, signal level, noise level or RERL, was actually
+// measured (127 means unavailable), and returns it unconverted.
+func decodeUnavailable127(raw uint8) (float32, bool) {
+	if raw == unavailable {
+		return 0, false
+	}
+	return float32(raw), true
+}
+
+// decodeMOS decodes an RFC 3611 §4.7.6 MOS byte (value*10, 127 meaning
+// unavailable) into its 1.0-5.0 score.
+func decodeMOS(raw uint8) (float32, bool) {
+	if raw == unavailable {
+		return 0, false
+	}
+	return float32(raw) / 10.0, true
+}
@@ -0,0 +1,140 @@
+package rtcpmetrics
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-metrics"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/negbie/rtcp"
+)
+
+// fakeSink records every SetGaugeWithLabels call, keyed by the gauge name
+// (the last element of key), so tests can assert on which gauges fired.
+type fakeSink struct {
+	gauges map[string]float32
+}
+
+func newFakeSink() *fakeSink {
+	return &fakeSink{gauges: map[string]float32{}}
+}
+
+func (f *fakeSink) SetGaugeWithLabels(key []string, val float32, _ []metrics.Label) {
+	f.gauges[key[len(key)-1]] = val
+}
+
+func TestObserveVoIPMetrics(t *testing.T) {
+	sink := newFakeSink()
+	registry := NewRegistry(sink, "rtcp", "voip")
+
+	xr := &rtcp.ExtendedReport{
+		SSRC: 1,
+		Reports: []rtcp.XRReportBlock{
+			&rtcp.VoIPMetricsReportBlock{
+				SSRC:                         2,
+				LossRate:                     128,
+				BurstDuration:                500,
+				SignalLevel:                  0xf6, // -10 as a signed byte
+				NoiseLevel:                   0xd8, // -40 as a signed byte
+				EchoReturnLoss:               5,
+				RFactor:                      93,
+				ExternalRFactor:              80,
+				MeanOpinionScoreListening:    42,
+				MeanOpinionScoreConversation: 41,
+			},
+		},
+	}
+
+	registry.Observe(xr, "")
+
+	assert.Equal(t, float32(0.5), sink.gauges["loss_rate"])
+	assert.Equal(t, float32(0.5), sink.gauges["burst_duration_seconds"])
+	assert.Equal(t, float32(-10), sink.gauges["signal_level_dbm"])
+	assert.Equal(t, float32(-40), sink.gauges["noise_level_dbm"])
+	assert.Equal(t, float32(5), sink.gauges["echo_return_loss_db"])
+	assert.Equal(t, float32(93), sink.gauges["r_factor"])
+	assert.Equal(t, float32(80), sink.gauges["external_r_factor"])
+	assert.Equal(t, float32(4.2), sink.gauges["mos_lq"])
+	assert.Equal(t, float32(4.1), sink.gauges["mos_cq"])
+}
+
+func TestObserveVoIPMetricsSkipsUnavailableFields(t *testing.T) {
+	sink := newFakeSink()
+	registry := NewRegistry(sink, "rtcp", "voip")
+
+	xr := &rtcp.ExtendedReport{
+		SSRC: 1,
+		Reports: []rtcp.XRReportBlock{
+			&rtcp.VoIPMetricsReportBlock{
+				SSRC:                         2,
+				SignalLevel:                  unavailable,
+				NoiseLevel:                   unavailable,
+				EchoReturnLoss:               unavailable,
+				RFactor:                      unavailable,
+				ExternalRFactor:              unavailable,
+				MeanOpinionScoreListening:    unavailable,
+				MeanOpinionScoreConversation: unavailable,
+			},
+		},
+	}
+
+	registry.Observe(xr, "")
+
+	for _, name := range []string{
+		"signal_level_dbm", "noise_level_dbm", "echo_return_loss_db",
+		"r_factor", "external_r_factor", "mos_lq", "mos_cq",
+	} {
+		_, reported := sink.gauges[name]
+		assert.Falsef(t, reported, "%s should not be reported when unavailable", name)
+	}
+}
+
+func TestObserveIgnoresNonVoIPReportBlocks(t *testing.T) {
+	sink := newFakeSink()
+	registry := NewRegistry(sink, "rtcp")
+
+	xr := &rtcp.ExtendedReport{
+		SSRC: 1,
+		Reports: []rtcp.XRReportBlock{
+			&rtcp.ReceiverReferenceTimeReportBlock{NTPTimestamp: 1},
+		},
+	}
+
+	registry.Observe(xr, "")
+
+	assert.Empty(t, sink.gauges)
+}
+
+func TestGaugeDoesNotAliasSharedPrefix(t *testing.T) {
+	sink := newFakeSink()
+	// Built with make+append so it has spare capacity, the way a caller
+	// might assemble a configurable label prefix and reuse it.
+	prefix := make([]string, 0, 4)
+	prefix = append(prefix, "rtcp", "voip")
+	registry := NewRegistry(sink, prefix...)
+
+	registry.gauge("loss_rate", 1, nil)
+	registry.gauge("discard_rate", 2, nil)
+
+	assert.Equal(t, float32(1), sink.gauges["loss_rate"])
+	assert.Equal(t, float32(2), sink.gauges["discard_rate"])
+	// The registry shares prefix's backing array; the spare slot past its
+	// length must not have been written with a metric name.
+	assert.Equal(t, "", prefix[:cap(prefix)][2])
+}
+
+func TestDecodeHelpers(t *testing.T) {
+	if _, ok := decodeUnavailable127(unavailable); ok {
+		t.Fatal("decodeUnavailable127(127) should report unavailable")
+	}
+	if val, ok := decodeUnavailable127(93); !ok || val != 93 {
+		t.Fatalf("decodeUnavailable127(93) = %v, %v; want 93, true", val, ok)
+	}
+
+	if _, ok := decodeMOS(unavailable); ok {
+		t.Fatal("decodeMOS(127) should report unavailable")
+	}
+	if val, ok := decodeMOS(42); !ok || val != 4.2 {
+		t.Fatalf("decodeMOS(42) = %v, %v; want 4.2, true", val, ok)
+	}
+}
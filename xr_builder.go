@@ -0,0 +1,374 @@
+package rtcp
+
+import (
+	"errors"
+	"time"
+)
+
+// errEmptyArrivalWindow is returned by XRBuilder.Build when given no RTP
+// arrivals to summarize.
+var errEmptyArrivalWindow = errors.New("rtcp: empty RTP arrival window")
+
+// voipMetricsUnavailable is the RFC 3611 section 4.7.6 sentinel meaning a
+// VoIP metrics field (R factor, MOS-LQ, MOS-CQ) was not measured.
+const voipMetricsUnavailable uint8 = 127
+
+// RTPArrivalEvent classifies a single RTP packet, per the RFC 3611
+// Appendix A.2 burst/gap state machine, as received, discarded by the
+// jitter buffer, or lost in transit.
+type RTPArrivalEvent uint8
+
+const (
+	RTPPacketReceived RTPArrivalEvent = iota
+	RTPPacketDiscarded
+	RTPPacketLost
+)
+
+// RTPArrival is one entry in the sliding window of RTP packets an
+// XRBuilder summarizes into a VoIPMetricsReportBlock. XRBuilder derives
+// each packet's RTPArrivalEvent itself from SequenceNumber, ArrivalTime
+// and PayloadSize; callers just report what they observed on the wire.
+type RTPArrival struct {
+	SequenceNumber uint16
+	ArrivalTime    time.Time
+	PayloadSize    int
+}
+
+// CodecInfo supplies the E-model inputs that are specific to the codec in
+// use, per ITU-T G.113.
+type CodecInfo struct {
+	// PacketizationInterval is the codec frame spacing (e.g. 20ms for
+	// G.711), used to turn burst/gap packet counts into durations.
+	PacketizationInterval time.Duration
+	// Ie is the codec's equipment impairment factor at zero packet loss.
+	Ie float64
+	// Bpl is the codec's packet-loss robustness factor.
+	Bpl float64
+}
+
+// NetworkStats carries the delay measurements an XRBuilder cannot derive
+// from the RTP arrival window by itself.
+type NetworkStats struct {
+	// OneWayDelay is the mouth-to-ear delay used to compute the E-model's
+	// delay impairment (Id).
+	OneWayDelay                      time.Duration
+	RoundTripDelay                   time.Duration
+	EndSystemDelay                   time.Duration
+	JitterBufferNominalDelay         time.Duration
+	JitterBufferMaximumDelay         time.Duration
+	JitterBufferAbsoluteMaximumDelay time.Duration
+}
+
+// XRBuilder computes a VoIPMetricsReportBlock from observed RTP stream
+// statistics, so sending-side code does not have to populate every byte of
+// the RFC 3611 VoIP Metrics Report Block by hand.
+type XRBuilder struct {
+	// Gmin is the RFC 3611 Appendix A.2 gap threshold: a run of at least
+	// Gmin consecutive good packets ends the current burst and starts a
+	// gap. Zero means use the RFC-recommended default of 16.
+	Gmin int
+}
+
+func (b *XRBuilder) gmin() int {
+	if b.Gmin <= 0 {
+		return 16
+	}
+	return b.Gmin
+}
+
+// Build summarizes arrivals, observed for an RTP stream using codec, into
+// a VoIPMetricsReportBlock for ssrc. net supplies the delay measurements
+// that cannot be derived from arrivals alone.
+func (b *XRBuilder) Build(ssrc uint32, arrivals []RTPArrival, codec CodecInfo, net NetworkStats) (*VoIPMetricsReportBlock, error) {
+	if len(arrivals) == 0 {
+		return nil, errEmptyArrivalWindow
+	}
+
+	events := classifyEvents(arrivals, codec, net)
+	lossRate, discardRate := packetRates(events)
+	bg := classifyBurstsAndGaps(events, b.gmin())
+	pktMs := float64(codec.PacketizationInterval.Milliseconds())
+
+	report := &VoIPMetricsReportBlock{
+		SSRC:                             ssrc,
+		LossRate:                         encodeQ8(lossRate),
+		DiscardRate:                      encodeQ8(discardRate),
+		BurstDensity:                     encodeQ8(bg.burstDensity()),
+		GapDensity:                       encodeQ8(bg.gapDensity()),
+		BurstDuration:                    clampUint16(bg.averageBurstPackets() * pktMs),
+		GapDuration:                      clampUint16(bg.averageGapPackets() * pktMs),
+		RoundTripDelay:                   clampUint16(float64(net.RoundTripDelay.Milliseconds())),
+		EndSystemDelay:                   clampUint16(float64(net.EndSystemDelay.Milliseconds())),
+		SignalLevel:                      voipMetricsUnavailable,
+		NoiseLevel:                       voipMetricsUnavailable,
+		EchoReturnLoss:                   voipMetricsUnavailable,
+		GapThreshold:                     uint8(b.gmin()),
+		JitterBufferNominalDelay:         clampUint16(float64(net.JitterBufferNominalDelay.Milliseconds())),
+		JitterBufferMaximumDelay:         clampUint16(float64(net.JitterBufferMaximumDelay.Milliseconds())),
+		JitterBufferAbsoluteMaximumDelay: clampUint16(float64(net.JitterBufferAbsoluteMaximumDelay.Milliseconds())),
+		ExternalRFactor:                  voipMetricsUnavailable,
+	}
+
+	if r, ok := eModelRFactor(net.OneWayDelay, lossRate*100, codec.Ie, codec.Bpl); ok {
+		mos := rFactorToMOS(r)
+		report.RFactor = encodeRFactor(r)
+		report.MeanOpinionScoreListening = encodeMOS(mos)
+		report.MeanOpinionScoreConversation = encodeMOS(mos)
+	} else {
+		report.RFactor = voipMetricsUnavailable
+		report.MeanOpinionScoreListening = voipMetricsUnavailable
+		report.MeanOpinionScoreConversation = voipMetricsUnavailable
+	}
+
+	return report, nil
+}
+
+// packetRates returns the fraction of events lost and discarded.
+func packetRates(events []RTPArrivalEvent) (lossRate, discardRate float64) {
+	var lost, discarded int
+	for _, e := range events {
+		switch e {
+		case RTPPacketLost:
+			lost++
+		case RTPPacketDiscarded:
+			discarded++
+		}
+	}
+
+	total := float64(len(events))
+	return float64(lost) / total, float64(discarded) / total
+}
+
+// seqGap returns the number of RTP sequence numbers strictly between prev
+// and cur, accounting for 16-bit wraparound (0 for consecutive packets).
+// A negative result means cur does not advance prev's sequence space (a
+// duplicate or reordered packet).
+func seqGap(prev, cur uint16) int {
+	return int(int16(cur-prev)) - 1
+}
+
+// isLate reports whether a packet that took hops sequence numbers to
+// arrive after prevArrival did so too late for jitter-buffer playout,
+// using codec's packetization interval to compute the expected spacing
+// and net's jitter buffer delay as the playout deadline. It reports false
+// whenever there isn't enough information to judge lateness.
+func isLate(prevArrival, arrival time.Time, hops int, codec CodecInfo, net NetworkStats) bool {
+	if codec.PacketizationInterval <= 0 {
+		return false
+	}
+	maxDelay := net.JitterBufferMaximumDelay
+	if maxDelay <= 0 {
+		maxDelay = net.JitterBufferNominalDelay
+	}
+	if maxDelay <= 0 {
+		return false
+	}
+
+	expected := time.Duration(hops) * codec.PacketizationInterval
+	return arrival.Sub(prevArrival)-expected > maxDelay
+}
+
+// classifyEvents derives each arrival's RTPArrivalEvent from sequence
+// number continuity and jitter-buffer timing, per the RFC 3611 Appendix
+// A.2 loss/discard definitions: a gap in sequence numbers means the
+// missing packets were lost, and a packet that repeats or fails to
+// advance the sequence space, arrives after the jitter buffer's playout
+// window, or carries an empty comfort-noise payload is discarded;
+// everything else counts as received.
+func classifyEvents(arrivals []RTPArrival, codec CodecInfo, net NetworkStats) []RTPArrivalEvent {
+	events := make([]RTPArrivalEvent, 0, len(arrivals))
+
+	var prev RTPArrival
+	havePrev := false
+	for _, a := range arrivals {
+		if !havePrev {
+			events = append(events, classifyArrival(a, false, time.Time{}, 1, codec, net))
+			prev, havePrev = a, true
+			continue
+		}
+
+		gap := seqGap(prev.SequenceNumber, a.SequenceNumber)
+		if gap < 0 {
+			events = append(events, RTPPacketDiscarded)
+			continue
+		}
+		for i := 0; i < gap; i++ {
+			events = append(events, RTPPacketLost)
+		}
+
+		events = append(events, classifyArrival(a, true, prev.ArrivalTime, gap+1, codec, net))
+		prev = a
+	}
+
+	return events
+}
+
+// classifyArrival classifies a single in-order arrival as discarded
+// (empty comfort-noise payload, or late per isLate) or received.
+func classifyArrival(a RTPArrival, havePrev bool, prevArrival time.Time, hops int, codec CodecInfo, net NetworkStats) RTPArrivalEvent {
+	if a.PayloadSize == 0 {
+		return RTPPacketDiscarded
+	}
+	if havePrev && isLate(prevArrival, a.ArrivalTime, hops, codec, net) {
+		return RTPPacketDiscarded
+	}
+	return RTPPacketReceived
+}
+
+// burstGapStats accumulates the packet, bad-packet and segment counts of
+// every burst and gap found by classifyBurstsAndGaps, from which RFC 3611's
+// averaged burst/gap density and duration fields are derived.
+type burstGapStats struct {
+	burstPackets, burstBad, burstCount int
+	gapPackets, gapBad, gapCount       int
+}
+
+func (s burstGapStats) burstDensity() float64 { return ratio(s.burstBad, s.burstPackets) }
+func (s burstGapStats) gapDensity() float64   { return ratio(s.gapBad, s.gapPackets) }
+
+func (s burstGapStats) averageBurstPackets() float64 { return ratio(s.burstPackets, s.burstCount) }
+func (s burstGapStats) averageGapPackets() float64   { return ratio(s.gapPackets, s.gapCount) }
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// classifyBurstsAndGaps runs the RFC 3611 Appendix A.2 state machine over
+// events: any lost or discarded packet starts or extends a burst, and a
+// run of at least gmin consecutive good packets ends the current burst and
+// starts a gap.
+func classifyBurstsAndGaps(events []RTPArrivalEvent, gmin int) burstGapStats {
+	var stats burstGapStats
+
+	inBurst := false
+	goodRun := 0
+	segPackets, segBad := 0, 0
+
+	closeBurst := func() {
+		if segPackets == 0 {
+			return
+		}
+		stats.burstCount++
+		stats.burstPackets += segPackets
+		stats.burstBad += segBad
+	}
+	closeGap := func() {
+		if segPackets == 0 {
+			return
+		}
+		stats.gapCount++
+		stats.gapPackets += segPackets
+		stats.gapBad += segBad
+	}
+
+	for _, e := range events {
+		bad := e != RTPPacketReceived
+
+		if !inBurst {
+			if bad {
+				closeGap()
+				inBurst = true
+				segPackets, segBad = 1, 1
+				goodRun = 0
+			} else {
+				segPackets++
+			}
+			continue
+		}
+
+		// inBurst
+		if bad {
+			segPackets++
+			segBad++
+			goodRun = 0
+			continue
+		}
+
+		goodRun++
+		segPackets++
+		if goodRun >= gmin {
+			// The last goodRun packets were only tentatively part of the
+			// burst; they actually belong to the gap that starts now.
+			segPackets -= goodRun
+			closeBurst()
+			inBurst = false
+			segPackets, segBad = goodRun, 0
+		}
+	}
+
+	if inBurst {
+		closeBurst()
+	} else {
+		closeGap()
+	}
+
+	return stats
+}
+
+// encodeQ8 encodes a 0.0-1.0 ratio as an RFC 3611 Q.8 fixed-point byte.
+func encodeQ8(ratio float64) uint8 {
+	return uint8(clampFloat(ratio*256, 0, 255))
+}
+
+// clampUint16 rounds down and clamps v to the range of a uint16.
+func clampUint16(v float64) uint16 {
+	return uint16(clampFloat(v, 0, 65535))
+}
+
+func clampFloat(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// eModelRFactor computes the simplified ITU-T G.107/G.113 E-model R factor
+// R = 93.2 - Id - Ie_eff for a codec with equipment impairment ie and
+// packet-loss robustness factor bpl, given the one-way delay and the
+// packet loss percentage (0-100) observed for the stream. ok is false when
+// bpl is not positive, since Ie_eff is undefined in that case.
+func eModelRFactor(oneWayDelay time.Duration, lossPercent, ie, bpl float64) (r float64, ok bool) {
+	if bpl <= 0 {
+		return 0, false
+	}
+
+	id := delayImpairment(float64(oneWayDelay.Milliseconds()))
+	ieEff := ie + (95-ie)*(lossPercent/(lossPercent/bpl+bpl))
+
+	r = 93.2 - id - ieEff
+	return clampFloat(r, 0, 100), true
+}
+
+// delayImpairment is the simplified one-way-delay impairment term Id of
+// the E-model, for a one-way delay d in milliseconds.
+func delayImpairment(d float64) float64 {
+	if d <= 0 {
+		return 0
+	}
+	if d <= 177.3 {
+		return 0.024 * d
+	}
+	return 0.024*d + 0.11*(d-177.3)
+}
+
+// rFactorToMOS converts an E-model R factor to a listening-quality MOS.
+func rFactorToMOS(r float64) float64 {
+	return 1 + 0.035*r + r*(r-60)*(100-r)*7e-6
+}
+
+// encodeRFactor encodes an R factor (0-100) as the raw byte RFC 3611
+// section 4.7.6 expects.
+func encodeRFactor(r float64) uint8 {
+	return uint8(clampFloat(r+0.5, 0, 100))
+}
+
+// encodeMOS encodes a 1.0-5.0 MOS as value*10, per RFC 3611 section 4.7.6.
+func encodeMOS(mos float64) uint8 {
+	return uint8(clampFloat(mos*10+0.5, 10, 50))
+}
@@ -0,0 +1,169 @@
+package rtcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXRBuilderBuildEmptyWindow(t *testing.T) {
+	b := &XRBuilder{}
+	report, err := b.Build(1, nil, CodecInfo{}, NetworkStats{})
+	assert.Nil(t, report)
+	assert.Equal(t, errEmptyArrivalWindow, err)
+}
+
+func TestXRBuilderBuildAllReceived(t *testing.T) {
+	b := &XRBuilder{}
+	arrivals := make([]RTPArrival, 10)
+	for i := range arrivals {
+		arrivals[i] = RTPArrival{SequenceNumber: uint16(i), PayloadSize: 160}
+	}
+
+	report, err := b.Build(7, arrivals, CodecInfo{PacketizationInterval: 20 * time.Millisecond}, NetworkStats{
+		RoundTripDelay: 100 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(7), report.SSRC)
+	assert.Equal(t, uint8(0), report.LossRate)
+	assert.Equal(t, uint8(0), report.DiscardRate)
+	assert.Equal(t, uint8(0), report.BurstDensity)
+	assert.Equal(t, uint8(0), report.GapDensity)
+	assert.Equal(t, uint16(100), report.RoundTripDelay)
+	assert.Equal(t, uint8(16), report.GapThreshold) // default Gmin
+	assert.Equal(t, voipMetricsUnavailable, report.SignalLevel)
+	assert.Equal(t, voipMetricsUnavailable, report.NoiseLevel)
+	assert.Equal(t, voipMetricsUnavailable, report.EchoReturnLoss)
+	// bpl is zero in CodecInfo{}, so the E-model can't run.
+	assert.Equal(t, voipMetricsUnavailable, report.RFactor)
+	assert.Equal(t, voipMetricsUnavailable, report.MeanOpinionScoreListening)
+}
+
+func TestXRBuilderBuildComputesRFactorWhenBplSet(t *testing.T) {
+	b := &XRBuilder{}
+	arrivals := []RTPArrival{
+		{SequenceNumber: 0, PayloadSize: 160},
+		{SequenceNumber: 1, PayloadSize: 160},
+	}
+
+	report, err := b.Build(1, arrivals, CodecInfo{Ie: 0, Bpl: 4.3}, NetworkStats{OneWayDelay: 50 * time.Millisecond})
+	assert.NoError(t, err)
+	assert.NotEqual(t, voipMetricsUnavailable, report.RFactor)
+	assert.NotEqual(t, voipMetricsUnavailable, report.MeanOpinionScoreListening)
+	assert.Equal(t, report.MeanOpinionScoreListening, report.MeanOpinionScoreConversation)
+}
+
+func TestClassifyBurstsAndGaps(t *testing.T) {
+	// 2 good, 1 lost, 2 good, 1 discarded, 2 good, with gmin=2: the two
+	// leading good packets form a gap, the lost packet opens a burst that
+	// the following 2 good packets (>= gmin) close, and so on.
+	events := []RTPArrivalEvent{
+		RTPPacketReceived,
+		RTPPacketReceived,
+		RTPPacketLost,
+		RTPPacketReceived,
+		RTPPacketReceived,
+		RTPPacketDiscarded,
+		RTPPacketReceived,
+		RTPPacketReceived,
+	}
+
+	stats := classifyBurstsAndGaps(events, 2)
+
+	// Each bad packet opens a 1-packet burst that the following gmin=2
+	// good packets immediately close, so every burst here is just the bad
+	// packet itself; those gmin good packets start the next gap instead.
+	assert.Equal(t, 2, stats.burstCount)
+	assert.Equal(t, 2, stats.burstPackets)
+	assert.Equal(t, 2, stats.burstBad)
+	assert.Equal(t, 3, stats.gapCount)
+	assert.Equal(t, 6, stats.gapPackets)
+	assert.Equal(t, 0, stats.gapBad)
+}
+
+func TestPacketRates(t *testing.T) {
+	events := []RTPArrivalEvent{
+		RTPPacketReceived,
+		RTPPacketLost,
+		RTPPacketDiscarded,
+		RTPPacketReceived,
+	}
+
+	lossRate, discardRate := packetRates(events)
+	assert.Equal(t, 0.25, lossRate)
+	assert.Equal(t, 0.25, discardRate)
+}
+
+func TestClassifyEventsSequenceGapIsLost(t *testing.T) {
+	arrivals := []RTPArrival{
+		{SequenceNumber: 0, PayloadSize: 160},
+		{SequenceNumber: 3, PayloadSize: 160}, // 2 missing sequence numbers
+	}
+
+	events := classifyEvents(arrivals, CodecInfo{}, NetworkStats{})
+	assert.Equal(t, []RTPArrivalEvent{
+		RTPPacketReceived,
+		RTPPacketLost,
+		RTPPacketLost,
+		RTPPacketReceived,
+	}, events)
+}
+
+func TestClassifyEventsDuplicateSequenceIsDiscarded(t *testing.T) {
+	arrivals := []RTPArrival{
+		{SequenceNumber: 5, PayloadSize: 160},
+		{SequenceNumber: 5, PayloadSize: 160}, // retransmit/duplicate
+	}
+
+	events := classifyEvents(arrivals, CodecInfo{}, NetworkStats{})
+	assert.Equal(t, []RTPArrivalEvent{RTPPacketReceived, RTPPacketDiscarded}, events)
+}
+
+func TestClassifyEventsEmptyPayloadIsDiscarded(t *testing.T) {
+	arrivals := []RTPArrival{
+		{SequenceNumber: 0, PayloadSize: 160},
+		{SequenceNumber: 1, PayloadSize: 0}, // comfort noise/DTX frame
+	}
+
+	events := classifyEvents(arrivals, CodecInfo{}, NetworkStats{})
+	assert.Equal(t, []RTPArrivalEvent{RTPPacketReceived, RTPPacketDiscarded}, events)
+}
+
+func TestClassifyEventsLateArrivalIsDiscarded(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	arrivals := []RTPArrival{
+		{SequenceNumber: 0, PayloadSize: 160, ArrivalTime: start},
+		// arrives 60ms after the previous packet, well past a 20ms frame
+		// plus a 20ms jitter buffer.
+		{SequenceNumber: 1, PayloadSize: 160, ArrivalTime: start.Add(60 * time.Millisecond)},
+	}
+
+	events := classifyEvents(arrivals, CodecInfo{PacketizationInterval: 20 * time.Millisecond}, NetworkStats{
+		JitterBufferMaximumDelay: 20 * time.Millisecond,
+	})
+	assert.Equal(t, []RTPArrivalEvent{RTPPacketReceived, RTPPacketDiscarded}, events)
+}
+
+func TestEncodeQ8(t *testing.T) {
+	assert.Equal(t, uint8(0), encodeQ8(0))
+	assert.Equal(t, uint8(128), encodeQ8(0.5))
+	assert.Equal(t, uint8(255), encodeQ8(1.5)) // clamped
+}
+
+func TestClampUint16(t *testing.T) {
+	assert.Equal(t, uint16(0), clampUint16(-5))
+	assert.Equal(t, uint16(1000), clampUint16(1000))
+	assert.Equal(t, uint16(65535), clampUint16(1e9))
+}
+
+func TestEModelRFactorUnavailableWhenBplNotPositive(t *testing.T) {
+	_, ok := eModelRFactor(0, 0, 0, 0)
+	assert.False(t, ok)
+}
+
+func TestEncodeRFactorAndMOS(t *testing.T) {
+	assert.Equal(t, uint8(93), encodeRFactor(93.2))
+	assert.Equal(t, uint8(10), encodeMOS(0)) // clamped to the 1.0 floor
+	assert.Equal(t, uint8(42), encodeMOS(4.2))
+}